@@ -0,0 +1,15 @@
+// Package cost estimates the monthly cost impact of a terraform plan.
+package cost
+
+// Estimate is the outcome of running a CostEstimator against a plan.
+type Estimate struct {
+	Currency         string
+	MonthlyCostDelta float64
+	Breakdown        string // raw, tool-specific diff output to show the user
+}
+
+// CostEstimator estimates the cost impact of a JSON plan (the output of
+// `terraform show -json`, written to planJSONPath).
+type CostEstimator interface {
+	Estimate(planJSONPath string) (Estimate, error)
+}
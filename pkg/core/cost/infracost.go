@@ -0,0 +1,72 @@
+package cost
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// InfracostEstimator runs `infracost breakdown` against a plan's JSON output
+// to estimate its monthly cost delta.
+type InfracostEstimator struct {
+	BinaryPath string // defaults to "infracost"
+	Currency   string
+	UsageFile  string
+}
+
+type infracostOutput struct {
+	Currency             string `json:"currency"`
+	PastTotalMonthlyCost string `json:"pastTotalMonthlyCost"`
+	TotalMonthlyCost     string `json:"totalMonthlyCost"`
+	DiffTotalMonthlyCost string `json:"diffTotalMonthlyCost"`
+}
+
+func (e InfracostEstimator) binaryPath() string {
+	if e.BinaryPath != "" {
+		return e.BinaryPath
+	}
+	return "infracost"
+}
+
+func (e InfracostEstimator) Estimate(planJSONPath string) (Estimate, error) {
+	args := []string{"breakdown", "--path", planJSONPath, "--format", "json"}
+	if e.Currency != "" {
+		args = append(args, "--currency", e.Currency)
+	}
+	if e.UsageFile != "" {
+		args = append(args, "--usage-file", e.UsageFile)
+	}
+
+	cmd := exec.Command(e.binaryPath(), args...)
+	cmd.Env = os.Environ()
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return Estimate{}, fmt.Errorf("error running infracost breakdown: %v: %s", err, stderr.Bytes())
+	}
+
+	var parsed infracostOutput
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return Estimate{}, fmt.Errorf("error parsing infracost output: %v", err)
+	}
+
+	delta, err := strconv.ParseFloat(parsed.DiffTotalMonthlyCost, 64)
+	if err != nil {
+		return Estimate{}, fmt.Errorf("error parsing diffTotalMonthlyCost %q: %v", parsed.DiffTotalMonthlyCost, err)
+	}
+
+	currency := parsed.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+
+	return Estimate{
+		Currency:         currency,
+		MonthlyCostDelta: delta,
+		Breakdown:        string(out),
+	}, nil
+}
@@ -0,0 +1,9 @@
+package cost
+
+// NoopEstimator is a CostEstimator that reports no cost impact. It's useful
+// for projects that haven't configured Infracost, and in tests.
+type NoopEstimator struct{}
+
+func (NoopEstimator) Estimate(planJSONPath string) (Estimate, error) {
+	return Estimate{}, nil
+}
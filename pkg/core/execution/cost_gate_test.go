@@ -0,0 +1,52 @@
+package execution
+
+import (
+	"digger/pkg/core/cost"
+	"encoding/json"
+	"testing"
+)
+
+type fakePlanStorage struct {
+	metadata map[string][]byte
+}
+
+func (f *fakePlanStorage) StorePlan(localPlanFilePath string, storedPlanFilePath string) error {
+	return nil
+}
+func (f *fakePlanStorage) RetrievePlan(localPlanFilePath string, storedPlanFilePath string) (*string, error) {
+	return nil, nil
+}
+func (f *fakePlanStorage) DeleteStoredPlan(storedPlanFilePath string) error { return nil }
+func (f *fakePlanStorage) PlanExists(storedPlanFilePath string) (bool, error) {
+	return false, nil
+}
+func (f *fakePlanStorage) StorePlanMetadata(storedPlanFilePath string, suffix string, data []byte) error {
+	if f.metadata == nil {
+		f.metadata = map[string][]byte{}
+	}
+	f.metadata[storedPlanFilePath+suffix] = data
+	return nil
+}
+func (f *fakePlanStorage) RetrievePlanMetadata(storedPlanFilePath string, suffix string) ([]byte, error) {
+	return f.metadata[storedPlanFilePath+suffix], nil
+}
+
+func TestCostGatePreApplyHook(t *testing.T) {
+	store := &fakePlanStorage{}
+	d := DiggerExecutor{PlanStorage: store, PlanPathProvider: ProjectPathProvider{ProjectName: "p", ProjectNamespace: "ns"}}
+
+	data, _ := json.Marshal(cost.Estimate{MonthlyCostDelta: 150, Currency: "USD"})
+	if err := store.StorePlanMetadata(d.PlanPathProvider.StoredPlanFilePath(), costEstimateSuffix, data); err != nil {
+		t.Fatalf("error seeding stored estimate: %v", err)
+	}
+
+	hook := CostGatePreApplyHook(100)
+	if err := hook(d); err == nil {
+		t.Errorf("expected hook to block apply when cost delta exceeds limit")
+	}
+
+	hook = CostGatePreApplyHook(200)
+	if err := hook(d); err != nil {
+		t.Errorf("expected hook to allow apply when cost delta is under limit, got: %v", err)
+	}
+}
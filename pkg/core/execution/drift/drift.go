@@ -0,0 +1,65 @@
+// Package drift detects changes made to infrastructure outside of Digger
+// (console edits, other tooling, a human running terraform locally) by
+// running a refresh-only plan and diffing it against the last known
+// snapshot.
+package drift
+
+import (
+	"digger/pkg/core/terraform"
+	"digger/pkg/core/terraform/planjson"
+	"fmt"
+)
+
+// Snapshot is the drifted state of a single project at a point in time.
+// Detect leaves DetectedAt empty; callers (ScheduledRun, DiggerExecutor.Drift)
+// stamp it.
+type Snapshot struct {
+	ProjectNamespace string
+	ProjectName      string
+	DetectedAt       string // RFC3339
+	Resources        []planjson.ResourceChange
+}
+
+// DriftSink is notified when a Detect call finds new drift.
+type DriftSink interface {
+	Notify(snapshot Snapshot) error
+}
+
+// DriftDetector runs a refresh-only plan for a single project and classifies
+// the result.
+type DriftDetector struct {
+	ProjectNamespace  string
+	ProjectName       string
+	TerraformExecutor terraform.TerraformExecutor
+	Sink              DriftSink
+	Envs              map[string]string
+}
+
+// Detect runs `terraform plan -detailed-exitcode -refresh-only`. It returns
+// a Snapshot and true if drift was found (exit code 2), an empty Snapshot
+// and false if not (exit code 0), and an error for anything else.
+func (d DriftDetector) Detect() (Snapshot, bool, error) {
+	exitCode, stdout, stderr, err := d.TerraformExecutor.PlanRefreshOnly(d.Envs)
+	if err != nil && exitCode != 2 {
+		return Snapshot{}, false, fmt.Errorf("error running refresh-only plan: %v: %v: %v", err, stdout, stderr)
+	}
+	if exitCode != 2 {
+		return Snapshot{}, false, nil
+	}
+
+	planJSON, err := d.TerraformExecutor.ShowPlanJSON("", d.Envs)
+	if err != nil {
+		return Snapshot{}, false, fmt.Errorf("error getting JSON plan output: %v", err)
+	}
+	summary, err := planjson.Parse([]byte(planJSON))
+	if err != nil {
+		return Snapshot{}, false, fmt.Errorf("error parsing JSON plan output: %v", err)
+	}
+
+	snapshot := Snapshot{
+		ProjectNamespace: d.ProjectNamespace,
+		ProjectName:      d.ProjectName,
+		Resources:        summary.ResourceChanges,
+	}
+	return snapshot, true, nil
+}
@@ -0,0 +1,21 @@
+package drift
+
+import (
+	"digger/pkg/core/terraform/planjson"
+	"testing"
+)
+
+func TestIsNewDrift(t *testing.T) {
+	previous := Snapshot{Resources: []planjson.ResourceChange{{Address: "aws_instance.a"}}}
+	current := Snapshot{Resources: []planjson.ResourceChange{{Address: "aws_instance.a"}, {Address: "aws_instance.b"}}}
+
+	if IsNewDrift(previous, current) != true {
+		t.Errorf("expected new drift when current has an address previous didn't")
+	}
+	if IsNewDrift(previous, previous) != false {
+		t.Errorf("expected no new drift when current matches previous exactly")
+	}
+	if IsNewDrift(Snapshot{}, current) != true {
+		t.Errorf("expected new drift when there was no previous snapshot")
+	}
+}
@@ -0,0 +1,60 @@
+package drift
+
+import (
+	"digger/pkg/core/storage"
+	"fmt"
+	"time"
+)
+
+// ScheduledRun is one project's worth of work for a scheduled (cron / GH
+// scheduled workflow) drift pass across the whole repo, run without any PR
+// context.
+type ScheduledRun struct {
+	Detector DriftDetector
+	Store    storage.PlanStorage
+}
+
+// RunAll runs drift detection for every project and only notifies the sink
+// for projects whose drift is new since the last run. It collects and
+// returns all per-project errors rather than stopping at the first one, so a
+// single broken project doesn't hide drift in the rest of the repo.
+func RunAll(runs []ScheduledRun) []error {
+	var errs []error
+	for _, run := range runs {
+		if err := run.runOne(); err != nil {
+			errs = append(errs, fmt.Errorf("%v#%v: %v", run.Detector.ProjectNamespace, run.Detector.ProjectName, err))
+		}
+	}
+	return errs
+}
+
+func (r ScheduledRun) runOne() error {
+	snapshot, drifted, err := r.Detector.Detect()
+	if err != nil {
+		return fmt.Errorf("error detecting drift: %v", err)
+	}
+	if !drifted {
+		return nil
+	}
+	snapshot.DetectedAt = time.Now().UTC().Format(time.RFC3339)
+
+	previous, err := LoadLastSnapshot(r.Store, r.Detector.ProjectNamespace, r.Detector.ProjectName)
+	if err != nil {
+		return fmt.Errorf("error loading previous drift snapshot: %v", err)
+	}
+
+	if err := StoreSnapshot(r.Store, snapshot); err != nil {
+		return fmt.Errorf("error storing drift snapshot: %v", err)
+	}
+
+	if !IsNewDrift(previous, snapshot) {
+		return nil
+	}
+	if r.Detector.Sink == nil {
+		return nil
+	}
+	if err := r.Detector.Sink.Notify(snapshot); err != nil {
+		return fmt.Errorf("error notifying drift sink: %v", err)
+	}
+	return nil
+}
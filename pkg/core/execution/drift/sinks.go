@@ -0,0 +1,72 @@
+package drift
+
+import (
+	"digger/pkg/core/reporting"
+	"digger/pkg/core/utils"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ReporterSink notifies drift through an existing Reporter (a PR comment or
+// whatever ReporterFactory handed back for a PR-less run).
+type ReporterSink struct {
+	Reporter reporting.Reporter
+}
+
+func (s ReporterSink) Notify(snapshot Snapshot) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Drift detected in %v#%v at %v:\n\n", snapshot.ProjectNamespace, snapshot.ProjectName, snapshot.DetectedAt)
+	for _, r := range snapshot.Resources {
+		fmt.Fprintf(&sb, "- %v (%v)\n", r.Address, strings.Join(r.Actions, ", "))
+	}
+	return s.Reporter.Report(sb.String(), utils.AsCollapsibleComment("Drift detected"))
+}
+
+// IssueSink is for drift found when there is no PR to comment on, e.g. a
+// scheduled run across the whole repo. It reports exactly as ReporterSink
+// does; whether that files/updates a tracking issue depends on the Reporter
+// it is given (e.g. one from ReporterFactory.IssueReporter).
+type IssueSink struct {
+	Reporter reporting.Reporter
+}
+
+func (s IssueSink) Notify(snapshot Snapshot) error {
+	return ReporterSink{Reporter: s.Reporter}.Notify(snapshot)
+}
+
+// SlackSink posts a drift notification to a Slack incoming webhook.
+type SlackSink struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+func (s SlackSink) Notify(snapshot Snapshot) error {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	text := fmt.Sprintf("Drift detected in %v#%v: %d resource(s) changed", snapshot.ProjectNamespace, snapshot.ProjectName, len(snapshot.Resources))
+	payload := fmt.Sprintf(`{"text": %q}`, text)
+	resp, err := client.Post(s.WebhookURL, "application/json", strings.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error posting to slack: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("slack webhook returned status %v", resp.StatusCode)
+	}
+	return nil
+}
+
+// GaugeSink records the number of drifted resources against a Prometheus
+// gauge, keyed by project. Set is the subset of prometheus.Gauge's API this
+// package needs, so it doesn't have to import the client library directly.
+type GaugeSink struct {
+	Set func(project string, drifted int)
+}
+
+func (s GaugeSink) Notify(snapshot Snapshot) error {
+	s.Set(snapshot.ProjectNamespace+"#"+snapshot.ProjectName, len(snapshot.Resources))
+	return nil
+}
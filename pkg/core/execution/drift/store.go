@@ -0,0 +1,50 @@
+package drift
+
+import (
+	"digger/pkg/core/storage"
+	"encoding/json"
+	"fmt"
+)
+
+func snapshotKey(namespace, project string) string {
+	return "drift/" + namespace + "/" + project + ".json"
+}
+
+// LoadLastSnapshot returns the last stored snapshot for a project, or a zero
+// Snapshot if none has been stored yet.
+func LoadLastSnapshot(store storage.PlanStorage, namespace, project string) (Snapshot, error) {
+	data, err := store.RetrievePlanMetadata(snapshotKey(namespace, project), "")
+	if err != nil || data == nil {
+		return Snapshot{}, nil
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("error parsing stored drift snapshot: %v", err)
+	}
+	return snapshot, nil
+}
+
+// StoreSnapshot persists the latest snapshot for a project.
+func StoreSnapshot(store storage.PlanStorage, snapshot Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("error marshalling drift snapshot: %v", err)
+	}
+	return store.StorePlanMetadata(snapshotKey(snapshot.ProjectNamespace, snapshot.ProjectName), "", data)
+}
+
+// IsNewDrift reports whether current contains any resource address not
+// present in the previous snapshot, so callers only re-notify on drift that
+// wasn't already reported.
+func IsNewDrift(previous, current Snapshot) bool {
+	seen := make(map[string]bool, len(previous.Resources))
+	for _, r := range previous.Resources {
+		seen[r.Address] = true
+	}
+	for _, r := range current.Resources {
+		if !seen[r.Address] {
+			return true
+		}
+	}
+	return false
+}
@@ -1,24 +1,47 @@
 package execution
 
 import (
+	"bytes"
+	"context"
+	"digger/pkg/core/cost"
+	"digger/pkg/core/execution/drift"
 	"digger/pkg/core/locking"
 	"digger/pkg/core/models"
+	"digger/pkg/core/policy"
 	"digger/pkg/core/reporting"
 	"digger/pkg/core/runners"
 	"digger/pkg/core/storage"
 	"digger/pkg/core/terraform"
+	"digger/pkg/core/terraform/planjson"
 	"digger/pkg/core/utils"
+	"digger/pkg/core/vcs"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"path"
 	"regexp"
 	"strings"
+	"time"
 )
 
+const policyDecisionSuffix = ".policy.json"
+const costEstimateSuffix = ".cost.json"
+
+// PostPlanHook runs after Plan's steps complete, given the plan's structured
+// summary. PreApplyHook runs before Apply's steps start. Both exist purely
+// for extensibility (e.g. a cost gate, a custom notification) without
+// DiggerExecutor having to know about every possible check up front.
+type PostPlanHook func(d DiggerExecutor, summary planjson.PlanSummary) error
+type PreApplyHook func(d DiggerExecutor) error
+
 type Executor interface {
 	Plan() (bool, string, error)
 	Apply() (bool, error)
+	// Drift runs a refresh-only plan to detect infrastructure changes made
+	// outside of Digger. It returns whether drift was found and a
+	// human-readable summary.
+	Drift() (bool, string, error)
 }
 
 type LockingExecutorWrapper struct {
@@ -53,6 +76,23 @@ func (l LockingExecutorWrapper) Apply() (bool, error) {
 	}
 }
 
+func (l LockingExecutorWrapper) Drift() (bool, string, error) {
+	locked, err := l.ProjectLock.Lock()
+	if err != nil {
+		return false, "", fmt.Errorf("error locking project: %v", err)
+	}
+	log.Printf("Lock result: %t\n", locked)
+	if !locked {
+		return false, "", nil
+	}
+	defer func() {
+		if _, err := l.ProjectLock.Unlock(); err != nil {
+			log.Printf("error unlocking project after drift check: %v\n", err)
+		}
+	}()
+	return l.Executor.Drift()
+}
+
 func (l LockingExecutorWrapper) Unlock() error {
 	err := l.ProjectLock.ForceUnlock()
 	if err != nil {
@@ -70,20 +110,44 @@ func (l LockingExecutorWrapper) Lock() error {
 }
 
 type DiggerExecutor struct {
-	ProjectNamespace  string
-	ProjectName       string
-	ProjectPath       string
-	StateEnvVars      map[string]string
-	CommandEnvVars    map[string]string
-	ApplyStage        *models.Stage
-	PlanStage         *models.Stage
-	CommandRunner     runners.CommandRun
-	TerraformExecutor terraform.TerraformExecutor
-	Reporter          reporting.Reporter
-	PlanStorage       storage.PlanStorage
-	PlanPathProvider  PlanPathProvider
+	ProjectNamespace            string
+	ProjectName                 string
+	ProjectPath                 string
+	StateEnvVars                map[string]string
+	CommandEnvVars              map[string]string
+	ApplyStage                  *models.Stage
+	PlanStage                   *models.Stage
+	CommandRunner               runners.CommandRun
+	TerraformExecutor           terraform.TerraformExecutor
+	Reporter                    reporting.Reporter
+	PlanStorage                 storage.PlanStorage
+	PlanPathProvider            PlanPathProvider
+	StateLocker                 terraform.StateLocker
+	StateLockingDisabled        bool
+	StateLockTimeout            time.Duration
+	DependencyLockfilePolicy    string
+	DependencyLockfilePlatforms []string
+	VCSCommitter                vcs.VCSCommitter
+	Branch                      string
+	PolicyChecker               policy.PolicyChecker
+	PolicyEnforcement           policy.EnforcementLevel
+	DriftSink                   drift.DriftSink
+	CostEstimator               cost.CostEstimator
+	PostPlanHooks               []PostPlanHook
+	PreApplyHooks               []PreApplyHook
 }
 
+// Dependency lockfile policies for .terraform.lock.hcl, set per-project via
+// `dependency_lockfile` in digger.yml.
+const (
+	DependencyLockfileIgnore        = "ignore"
+	DependencyLockfileCommit        = "commit"
+	DependencyLockfileEnforce       = "enforce"
+	DependencyLockfileMultiplatform = "multiplatform"
+)
+
+const dependencyLockfileName = ".terraform.lock.hcl"
+
 type PlanPathProvider interface {
 	LocalPlanFilePath() string
 	StoredPlanFilePath() string
@@ -108,9 +172,166 @@ func (d ProjectPathProvider) StoredPlanFilePath() string {
 	return path.Join(d.ProjectNamespace, d.PlanFileName())
 }
 
+// defaultStateLockTimeout is used when a project configures a StateLocker
+// but leaves StateLockTimeout unset.
+const defaultStateLockTimeout = 3 * time.Minute
+
+// lockState acquires the remote state lock for the duration of a terraform
+// run, in addition to Digger's own per-project lock (LockingExecutorWrapper).
+// This stops a concurrent PR, an out-of-band CI run, or a human running
+// terraform locally from stomping on the same state file. It is a no-op if
+// state locking is disabled or no StateLocker was configured.
+func (d DiggerExecutor) lockState() (func(), error) {
+	if d.StateLockingDisabled || d.StateLocker == nil {
+		return func() {}, nil
+	}
+	timeout := d.StateLockTimeout
+	if timeout == 0 {
+		timeout = defaultStateLockTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := d.StateLocker.LockState(ctx); err != nil {
+		holder, holderErr := d.StateLocker.CurrentLockHolder(context.Background())
+		if holderErr == nil && holder != nil && d.Reporter != nil {
+			msg := fmt.Sprintf("State is locked by %v (operation: %v, since %v).", holder.Who, holder.Operation, holder.Created)
+			_ = d.Reporter.Report(msg, utils.AsCollapsibleComment("Could not acquire state lock"))
+		}
+		return func() {}, fmt.Errorf("error acquiring state lock: %v", err)
+	}
+	return func() {
+		if err := d.StateLocker.UnlockState(context.Background()); err != nil {
+			log.Printf("error releasing state lock: %v\n", err)
+		}
+	}, nil
+}
+
+// handleDependencyLockfile applies the project's dependency_lockfile policy
+// once init has run. Terraform writes/rewrites .terraform.lock.hcl whenever
+// it resolves new provider versions. Depending on policy this either deletes
+// the file if init created it (ignore; an existing file that init rewrote is
+// left as it is), commits it back to the PR branch so
+// provider versions are pinned (commit/multiplatform), or fails the plan if
+// it drifted from what's checked in (enforce) — whether that drift is the
+// file appearing for the first time or init rewriting an existing file's
+// contents (e.g. a provider version bump changing hashes).
+func (d DiggerExecutor) handleDependencyLockfile(existedBeforeInit bool, contentBeforeInit []byte, lockfilePath string) error {
+	contentAfterInit, readErr := os.ReadFile(lockfilePath)
+	existsAfterInit := readErr == nil
+	createdByInit := !existedBeforeInit && existsAfterInit
+	driftedByInit := existedBeforeInit && existsAfterInit && !bytes.Equal(contentBeforeInit, contentAfterInit)
+
+	switch d.DependencyLockfilePolicy {
+	case "", DependencyLockfileIgnore:
+		if createdByInit {
+			if rmErr := os.Remove(lockfilePath); rmErr != nil {
+				return fmt.Errorf("error removing %v created by init: %v", dependencyLockfileName, rmErr)
+			}
+		}
+		return nil
+	case DependencyLockfileEnforce:
+		if createdByInit {
+			return fmt.Errorf("%v is missing from the repo and dependency_lockfile policy is 'enforce'", dependencyLockfileName)
+		}
+		if driftedByInit {
+			return fmt.Errorf("%v drifted from what's checked into the repo and dependency_lockfile policy is 'enforce'", dependencyLockfileName)
+		}
+		return nil
+	case DependencyLockfileCommit, DependencyLockfileMultiplatform:
+		if !createdByInit && !driftedByInit {
+			return nil
+		}
+		if d.VCSCommitter == nil {
+			return fmt.Errorf("dependency_lockfile policy is '%v' but no VCSCommitter is configured", d.DependencyLockfilePolicy)
+		}
+		repoPath := path.Join(d.ProjectPath, dependencyLockfileName)
+		msg := fmt.Sprintf("Add %v for %v#%v", dependencyLockfileName, d.ProjectNamespace, d.ProjectName)
+		if driftedByInit {
+			msg = fmt.Sprintf("Update %v for %v#%v", dependencyLockfileName, d.ProjectNamespace, d.ProjectName)
+		}
+		if err := d.VCSCommitter.CommitFile(d.Branch, repoPath, contentAfterInit, msg); err != nil {
+			return fmt.Errorf("error committing %v: %v", dependencyLockfileName, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown dependency_lockfile policy: %v", d.DependencyLockfilePolicy)
+	}
+}
+
+// runCostEstimate runs the configured CostEstimator against the plan's JSON
+// output, reports the diff, and persists it alongside the stored plan so a
+// CostGatePreApplyHook can later enforce its limit at Apply time against the
+// same estimate the PR saw.
+func (d DiggerExecutor) runCostEstimate(planJSONPath string) error {
+	if d.CostEstimator == nil || planJSONPath == "" {
+		return nil
+	}
+	estimate, err := d.CostEstimator.Estimate(planJSONPath)
+	if err != nil {
+		return fmt.Errorf("error estimating cost: %v", err)
+	}
+
+	if d.Reporter != nil {
+		if err := d.Reporter.Report(reporting.FormatCostEstimateMarkdown(estimate), utils.AsCollapsibleComment("Cost estimate")); err != nil {
+			log.Printf("error publishing cost estimate: %v\n", err)
+		}
+	}
+
+	if d.PlanStorage != nil {
+		data, err := json.Marshal(estimate)
+		if err != nil {
+			return fmt.Errorf("error marshalling cost estimate: %v", err)
+		}
+		if err := d.PlanStorage.StorePlanMetadata(d.PlanPathProvider.StoredPlanFilePath(), costEstimateSuffix, data); err != nil {
+			return fmt.Errorf("error storing cost estimate: %v", err)
+		}
+	}
+	return nil
+}
+
+// CostGatePreApplyHook builds a PreApplyHook that refuses Apply if the cost
+// estimate stored during Plan exceeds maxMonthlyCostDelta. It's the built-in
+// use of the generic PreApplyHook/PostPlanHook extensibility point: projects
+// that want to block applies on cost append it to PreApplyHooks themselves,
+// e.g. `d.PreApplyHooks = append(d.PreApplyHooks, execution.CostGatePreApplyHook(100))`.
+func CostGatePreApplyHook(maxMonthlyCostDelta float64) PreApplyHook {
+	return func(d DiggerExecutor) error {
+		if d.PlanStorage == nil {
+			return nil
+		}
+		data, err := d.PlanStorage.RetrievePlanMetadata(d.PlanPathProvider.StoredPlanFilePath(), costEstimateSuffix)
+		if err != nil || data == nil {
+			return nil
+		}
+		var estimate cost.Estimate
+		if err := json.Unmarshal(data, &estimate); err != nil {
+			return fmt.Errorf("error parsing stored cost estimate: %v", err)
+		}
+		if estimate.MonthlyCostDelta > maxMonthlyCostDelta {
+			return fmt.Errorf("apply blocked: monthly cost delta %.2f %v exceeds limit %.2f", estimate.MonthlyCostDelta, estimate.Currency, maxMonthlyCostDelta)
+		}
+		return nil
+	}
+}
+
 func (d DiggerExecutor) Plan() (bool, string, error) {
 	plan := ""
 	var planSteps []models.Step
+	var lastPlanJSONPath string
+	var lastPlanSummary planjson.PlanSummary
+	defer func() {
+		if lastPlanJSONPath != "" {
+			if err := os.Remove(lastPlanJSONPath); err != nil && !os.IsNotExist(err) {
+				log.Printf("error removing temporary JSON plan %v: %v\n", lastPlanJSONPath, err)
+			}
+		}
+	}()
+
+	unlockState, err := d.lockState()
+	if err != nil {
+		return false, "", err
+	}
+	defer unlockState()
 
 	if d.PlanStage != nil {
 		planSteps = d.PlanStage.Steps
@@ -126,10 +347,28 @@ func (d DiggerExecutor) Plan() (bool, string, error) {
 	}
 	for _, step := range planSteps {
 		if step.Action == "init" {
+			lockfilePath := path.Join(d.ProjectPath, dependencyLockfileName)
+			contentBeforeInit, statErr := os.ReadFile(lockfilePath)
+			lockfileExisted := statErr == nil
+
 			_, _, err := d.TerraformExecutor.Init(step.ExtraArgs, d.StateEnvVars)
 			if err != nil {
 				return false, "", fmt.Errorf("error running init: %v", err)
 			}
+
+			if err := d.handleDependencyLockfile(lockfileExisted, contentBeforeInit, lockfilePath); err != nil {
+				return false, "", err
+			}
+		}
+		if step.Action == "providers_lock" {
+			platforms := step.ExtraArgs
+			if len(platforms) == 0 {
+				platforms = d.DependencyLockfilePlatforms
+			}
+			_, _, err := d.TerraformExecutor.ProvidersLock(platforms, d.CommandEnvVars)
+			if err != nil {
+				return false, "", fmt.Errorf("error running providers lock: %v", err)
+			}
 		}
 		if step.Action == "plan" {
 			planArgs := []string{"-out", d.PlanPathProvider.PlanFileName()}
@@ -138,7 +377,10 @@ func (d DiggerExecutor) Plan() (bool, string, error) {
 			if err != nil {
 				return false, "", fmt.Errorf("error executing plan: %v", err)
 			}
-			if d.PlanStorage != nil {
+			// When the TerraformExecutor is backed by a remote system (e.g.
+			// RemoteBackendExecutor), the remote run ID is itself the plan's
+			// identity, so Digger's own PlanStorage is short-circuited.
+			if d.PlanStorage != nil && !d.usesRemotePlanIdentity() {
 				planExists, err := d.PlanStorage.PlanExists(d.PlanPathProvider.StoredPlanFilePath())
 				if err != nil {
 					return false, "", fmt.Errorf("error checking if plan exists: %v", err)
@@ -160,6 +402,53 @@ func (d DiggerExecutor) Plan() (bool, string, error) {
 			if err != nil {
 				fmt.Printf("error publishing comment: %v", err)
 			}
+
+			if isNonEmptyPlan {
+				// A remote backend (e.g. RemoteBackendExecutor) has no local
+				// plan file to point ShowPlanJSON at; it resolves its own
+				// last run internally, so pass it nothing to override.
+				showPlanJSONPath := d.PlanPathProvider.LocalPlanFilePath()
+				if d.usesRemotePlanIdentity() {
+					showPlanJSONPath = ""
+				}
+				planJSON, err := d.TerraformExecutor.ShowPlanJSON(showPlanJSONPath, d.CommandEnvVars)
+				if err != nil {
+					log.Printf("error getting JSON plan output: %v\n", err)
+					if policyErr := d.policyCheckUnavailable(fmt.Sprintf("could not get JSON plan output: %v", err)); policyErr != nil {
+						log.Printf("error recording policy decision: %v\n", policyErr)
+					}
+				} else {
+					summary, err := planjson.Parse([]byte(planJSON))
+					if err != nil {
+						log.Printf("error parsing JSON plan output: %v\n", err)
+						if policyErr := d.policyCheckUnavailable(fmt.Sprintf("could not parse JSON plan output: %v", err)); policyErr != nil {
+							log.Printf("error recording policy decision: %v\n", policyErr)
+						}
+					} else {
+						lastPlanSummary = summary
+						if d.Reporter != nil {
+							if err := d.Reporter.ReportPlanSummary(summary); err != nil {
+								log.Printf("error reporting plan summary: %v\n", err)
+							}
+						}
+						if err := d.runPolicyCheck([]byte(planJSON)); err != nil {
+							log.Printf("error running policy check: %v\n", err)
+						}
+					}
+
+					jsonPath := d.PlanPathProvider.LocalPlanFilePath() + ".json"
+					if err := os.WriteFile(jsonPath, []byte(planJSON), 0644); err != nil {
+						log.Printf("error writing JSON plan to disk: %v\n", err)
+					} else {
+						lastPlanJSONPath = jsonPath
+					}
+				}
+			}
+		}
+		if step.Action == "cost" {
+			if err := d.runCostEstimate(lastPlanJSONPath); err != nil {
+				log.Printf("error running cost estimate: %v\n", err)
+			}
 		}
 		if step.Action == "run" {
 			var commands []string
@@ -174,12 +463,129 @@ func (d DiggerExecutor) Plan() (bool, string, error) {
 			}
 		}
 	}
+
+	for _, hook := range d.PostPlanHooks {
+		if err := hook(d, lastPlanSummary); err != nil {
+			return false, plan, fmt.Errorf("post-plan hook failed: %v", err)
+		}
+	}
+
 	return true, plan, nil
 }
 
+// runPolicyCheck evaluates the project's PolicyChecker (if configured)
+// against the JSON plan and persists the resulting Decision alongside the
+// stored plan, so a later Apply of the same plan file picks up the same
+// decision rather than being able to bypass the check. A failure of the
+// checker itself (as opposed to a rule violation) is recorded with
+// CheckerError set, so SoftFail still lets Apply through but HardFail
+// doesn't.
+func (d DiggerExecutor) runPolicyCheck(planJSON []byte) error {
+	if d.PolicyChecker == nil {
+		return nil
+	}
+	decision, err := d.PolicyChecker.Check(planJSON, d.PolicyEnforcement)
+	if err != nil {
+		decision = policy.Decision{Enforcement: d.PolicyEnforcement, CheckerError: true, Violations: []policy.Violation{{Rule: "policy-checker", Message: err.Error()}}}
+	}
+	if persistErr := d.persistPolicyDecision(decision); persistErr != nil {
+		return persistErr
+	}
+	return err
+}
+
+// policyCheckUnavailable records a blocking policy decision for a project
+// with a PolicyChecker configured whose plan JSON couldn't even be obtained
+// (ShowPlanJSON/planjson.Parse failing), so that case can't silently bypass
+// the same enforcement runPolicyCheck would otherwise have applied.
+func (d DiggerExecutor) policyCheckUnavailable(reason string) error {
+	if d.PolicyChecker == nil {
+		return nil
+	}
+	decision := policy.Decision{
+		Enforcement:  d.PolicyEnforcement,
+		CheckerError: true,
+		Violations:   []policy.Violation{{Rule: "policy-checker", Message: reason}},
+	}
+	return d.persistPolicyDecision(decision)
+}
+
+// persistPolicyDecision reports any violations and stores the decision
+// alongside the plan so checkPolicyDecision can enforce it at Apply time.
+func (d DiggerExecutor) persistPolicyDecision(decision policy.Decision) error {
+	if len(decision.Violations) > 0 && d.Reporter != nil {
+		var sb strings.Builder
+		for _, v := range decision.Violations {
+			fmt.Fprintf(&sb, "- **%v**: %v\n", v.Rule, v.Message)
+		}
+		if reportErr := d.Reporter.Report(sb.String(), utils.AsCollapsibleComment("Policy violations")); reportErr != nil {
+			log.Printf("error publishing policy violations: %v\n", reportErr)
+		}
+	}
+
+	if d.PlanStorage != nil {
+		data, marshalErr := json.Marshal(decision)
+		if marshalErr != nil {
+			return fmt.Errorf("error marshalling policy decision: %v", marshalErr)
+		}
+		if storeErr := d.PlanStorage.StorePlanMetadata(d.PlanPathProvider.StoredPlanFilePath(), policyDecisionSuffix, data); storeErr != nil {
+			return fmt.Errorf("error storing policy decision: %v", storeErr)
+		}
+	}
+	return nil
+}
+
+// checkPolicyDecision loads the policy decision stored by the Plan that
+// produced the plan file about to be applied and refuses to proceed if it
+// blocks Apply, regardless of whether the project lock is held.
+func (d DiggerExecutor) checkPolicyDecision() error {
+	if d.PlanStorage == nil {
+		return nil
+	}
+	data, err := d.PlanStorage.RetrievePlanMetadata(d.PlanPathProvider.StoredPlanFilePath(), policyDecisionSuffix)
+	if err != nil || data == nil {
+		return nil
+	}
+	var decision policy.Decision
+	if err := json.Unmarshal(data, &decision); err != nil {
+		return fmt.Errorf("error parsing stored policy decision: %v", err)
+	}
+	if decision.Blocks() {
+		return fmt.Errorf("apply blocked by policy (%v): %d violation(s)", decision.Enforcement, len(decision.Violations))
+	}
+	return nil
+}
+
+// usesRemotePlanIdentity reports whether d.TerraformExecutor delegates to a
+// remote backend (e.g. Terraform Cloud) where the run ID is itself the
+// plan's identity, so Digger's local PlanStorage should be bypassed.
+func (d DiggerExecutor) usesRemotePlanIdentity() bool {
+	_, ok := d.TerraformExecutor.(terraform.RemotePlanIdentifier)
+	return ok
+}
+
 func (d DiggerExecutor) Apply() (bool, error) {
+	unlockState, err := d.lockState()
+	if err != nil {
+		return false, err
+	}
+	defer unlockState()
+
+	if err := d.checkPolicyDecision(); err != nil {
+		return false, err
+	}
+
+	for _, hook := range d.PreApplyHooks {
+		if err := hook(d); err != nil {
+			return false, fmt.Errorf("pre-apply hook failed: %v", err)
+		}
+	}
+
 	var plansFilename *string
-	if d.PlanStorage != nil {
+	if identifier, ok := d.TerraformExecutor.(terraform.RemotePlanIdentifier); ok {
+		runID := identifier.PlanIdentity()
+		plansFilename = &runID
+	} else if d.PlanStorage != nil {
 		var err error
 		plansFilename, err = d.PlanStorage.RetrievePlan(d.PlanPathProvider.LocalPlanFilePath(), d.PlanPathProvider.StoredPlanFilePath())
 		if err != nil {
@@ -242,6 +648,55 @@ func (d DiggerExecutor) Apply() (bool, error) {
 	return true, nil
 }
 
+// Drift runs a refresh-only plan to detect infrastructure changes made
+// outside of Digger (console edits, other tooling, a human running
+// terraform locally). Unlike Plan/Apply this has no PR to comment on when
+// invoked from a scheduler, so it only reports through d.DriftSink and
+// leaves deciding how/whether to notify (PR comment, issue, Slack, a
+// Prometheus gauge) to the caller's choice of sink.
+func (d DiggerExecutor) Drift() (bool, string, error) {
+	detector := drift.DriftDetector{
+		ProjectNamespace:  d.ProjectNamespace,
+		ProjectName:       d.ProjectName,
+		TerraformExecutor: d.TerraformExecutor,
+		Sink:              d.DriftSink,
+		Envs:              d.CommandEnvVars,
+	}
+
+	snapshot, drifted, err := detector.Detect()
+	if err != nil {
+		return false, "", fmt.Errorf("error detecting drift: %v", err)
+	}
+	if !drifted {
+		return false, "", nil
+	}
+	snapshot.DetectedAt = time.Now().UTC().Format(time.RFC3339)
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "%d resource(s) drifted in %v#%v", len(snapshot.Resources), d.ProjectNamespace, d.ProjectName)
+
+	if d.PlanStorage != nil {
+		previous, err := drift.LoadLastSnapshot(d.PlanStorage, d.ProjectNamespace, d.ProjectName)
+		if err != nil {
+			return true, summary.String(), fmt.Errorf("error loading previous drift snapshot: %v", err)
+		}
+		if err := drift.StoreSnapshot(d.PlanStorage, snapshot); err != nil {
+			return true, summary.String(), fmt.Errorf("error storing drift snapshot: %v", err)
+		}
+		if !drift.IsNewDrift(previous, snapshot) {
+			return true, summary.String(), nil
+		}
+	}
+
+	if d.DriftSink != nil {
+		if err := d.DriftSink.Notify(snapshot); err != nil {
+			return true, summary.String(), fmt.Errorf("error notifying drift sink: %v", err)
+		}
+	}
+
+	return true, summary.String(), nil
+}
+
 func cleanupTerraformOutput(nonEmptyOutput bool, planError error, stdout string, stderr string, regexStr *string) string {
 	var errorStr, start string
 
@@ -0,0 +1,81 @@
+package execution
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type fakeCommitter struct {
+	committed bool
+}
+
+func (f *fakeCommitter) CommitFile(branch string, path string, content []byte, message string) error {
+	f.committed = true
+	return nil
+}
+
+func writeLockfile(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, dependencyLockfileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("error writing lockfile: %v", err)
+	}
+	return path
+}
+
+func TestHandleDependencyLockfileEnforce(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("missing lockfile created by init fails", func(t *testing.T) {
+		path := writeLockfile(t, dir, "v2")
+		d := DiggerExecutor{DependencyLockfilePolicy: DependencyLockfileEnforce}
+		if err := d.handleDependencyLockfile(false, nil, path); err == nil {
+			t.Errorf("expected error when lockfile was created by init")
+		}
+	})
+
+	t.Run("unchanged lockfile passes", func(t *testing.T) {
+		path := writeLockfile(t, dir, "v2")
+		d := DiggerExecutor{DependencyLockfilePolicy: DependencyLockfileEnforce}
+		if err := d.handleDependencyLockfile(true, []byte("v2"), path); err != nil {
+			t.Errorf("expected no error for unchanged lockfile, got: %v", err)
+		}
+	})
+
+	t.Run("content drift fails", func(t *testing.T) {
+		path := writeLockfile(t, dir, "v2")
+		d := DiggerExecutor{DependencyLockfilePolicy: DependencyLockfileEnforce}
+		if err := d.handleDependencyLockfile(true, []byte("v1"), path); err == nil {
+			t.Errorf("expected error when lockfile content drifted during init")
+		}
+	})
+}
+
+func TestHandleDependencyLockfileCommit(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("drifted content is committed", func(t *testing.T) {
+		path := writeLockfile(t, dir, "v2")
+		committer := &fakeCommitter{}
+		d := DiggerExecutor{DependencyLockfilePolicy: DependencyLockfileCommit, VCSCommitter: committer}
+		if err := d.handleDependencyLockfile(true, []byte("v1"), path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !committer.committed {
+			t.Errorf("expected drifted lockfile to be committed")
+		}
+	})
+
+	t.Run("unchanged content is not committed", func(t *testing.T) {
+		path := writeLockfile(t, dir, "v2")
+		committer := &fakeCommitter{}
+		d := DiggerExecutor{DependencyLockfilePolicy: DependencyLockfileCommit, VCSCommitter: committer}
+		if err := d.handleDependencyLockfile(true, []byte("v2"), path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if committer.committed {
+			t.Errorf("expected unchanged lockfile not to be committed")
+		}
+	})
+}
@@ -0,0 +1,10 @@
+package locking
+
+// ProjectLock serializes Plan/Apply runs for a single project so that two
+// PRs (or a PR and an out-of-band run) don't race each other.
+type ProjectLock interface {
+	Lock() (bool, error)
+	Unlock() (bool, error)
+	ForceUnlock() error
+	LockId() string
+}
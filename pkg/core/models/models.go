@@ -0,0 +1,41 @@
+package models
+
+// Step is a single unit of work in a Stage's pipeline, e.g. "init", "plan",
+// "apply" or an arbitrary shell "run" command.
+type Step struct {
+	Action    string
+	Value     string
+	ExtraArgs []string
+	Shell     string
+}
+
+// Stage is an ordered list of Steps, used to describe a project's plan or
+// apply pipeline.
+type Stage struct {
+	Steps []Step
+}
+
+// BackendType selects how a project's terraform runs are actually executed.
+type BackendType string
+
+const (
+	BackendLocal  BackendType = "local"
+	BackendRemote BackendType = "remote"
+)
+
+// BackendConfig is a project's `backend:` config block. Type selects between
+// running terraform locally and delegating to Terraform Cloud/Enterprise;
+// Remote is only populated when Type is BackendRemote.
+type BackendConfig struct {
+	Type   BackendType
+	Remote *RemoteConfig
+}
+
+// RemoteConfig is the `backend: remote { ... }` block: enough to address a
+// TFC/TFE workspace and the run triggered against it.
+type RemoteConfig struct {
+	Hostname     string
+	Organization string
+	Workspace    string
+	TokenEnv     string
+}
@@ -0,0 +1,57 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// ConftestChecker evaluates a plan against a Rego policy directory via
+// `conftest test`.
+type ConftestChecker struct {
+	PolicyDir string
+}
+
+type conftestResult struct {
+	Failures []struct {
+		Msg string `json:"msg"`
+	} `json:"failures"`
+	Warnings []struct {
+		Msg string `json:"msg"`
+	} `json:"warnings"`
+}
+
+func (c ConftestChecker) Check(planJSON []byte, enforcement EnforcementLevel) (Decision, error) {
+	tmpFile, err := os.CreateTemp("", "digger-plan-*.json")
+	if err != nil {
+		return Decision{}, fmt.Errorf("error creating temp plan file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(planJSON); err != nil {
+		return Decision{}, fmt.Errorf("error writing temp plan file: %v", err)
+	}
+	tmpFile.Close()
+
+	cmd := exec.Command("conftest", "test", "--output", "json", "--policy", c.PolicyDir, tmpFile.Name())
+	cmd.Env = os.Environ()
+	out, _ := cmd.CombinedOutput() // conftest exits non-zero on failures, that's expected
+
+	var results []conftestResult
+	if err := json.Unmarshal(out, &results); err != nil {
+		return Decision{}, fmt.Errorf("error parsing conftest output: %v: %s", err, out)
+	}
+
+	var violations []Violation
+	for _, r := range results {
+		for _, f := range r.Failures {
+			violations = append(violations, Violation{Rule: "conftest", Message: f.Msg})
+		}
+	}
+
+	return Decision{
+		Allowed:     len(violations) == 0,
+		Enforcement: enforcement,
+		Violations:  violations,
+	}, nil
+}
@@ -0,0 +1,53 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// OPAChecker evaluates a plan against an Open Policy Agent bundle by
+// shelling out to `opa eval`.
+type OPAChecker struct {
+	BundlePath string
+	Query      string // e.g. "data.digger.deny"
+}
+
+type opaResultSet struct {
+	Result []struct {
+		Expressions []struct {
+			Value []string `json:"value"`
+		} `json:"expressions"`
+	} `json:"result"`
+}
+
+func (o OPAChecker) Check(planJSON []byte, enforcement EnforcementLevel) (Decision, error) {
+	cmd := exec.Command("opa", "eval", "--format", "json", "--bundle", o.BundlePath, "--stdin-input", o.Query)
+	cmd.Stdin = bytes.NewReader(planJSON)
+	cmd.Env = os.Environ()
+
+	out, err := cmd.Output()
+	if err != nil {
+		return Decision{}, fmt.Errorf("error running opa eval: %v", err)
+	}
+
+	var result opaResultSet
+	if err := json.Unmarshal(out, &result); err != nil {
+		return Decision{}, fmt.Errorf("error parsing opa output: %v", err)
+	}
+
+	var violations []Violation
+	if len(result.Result) > 0 && len(result.Result[0].Expressions) > 0 {
+		for _, msg := range result.Result[0].Expressions[0].Value {
+			violations = append(violations, Violation{Rule: o.Query, Message: msg})
+		}
+	}
+
+	return Decision{
+		Allowed:     len(violations) == 0,
+		Enforcement: enforcement,
+		Violations:  violations,
+	}, nil
+}
@@ -0,0 +1,57 @@
+// Package policy lets a project gate Apply on a policy-as-code check (OPA,
+// Conftest, or an arbitrary webhook) that runs against the JSON plan produced
+// after a successful Plan. There is no built-in Sentinel checker.
+package policy
+
+// EnforcementLevel controls what happens when a PolicyChecker finds
+// violations.
+type EnforcementLevel string
+
+const (
+	// Advisory: violations are reported but Apply still proceeds.
+	Advisory EnforcementLevel = "advisory"
+	// SoftFail: violations are reported and Apply is blocked, but a checker
+	// error (as opposed to a violation) does not block Apply.
+	SoftFail EnforcementLevel = "soft-fail"
+	// HardFail: violations OR a checker error both block Apply, even if the
+	// project lock is held.
+	HardFail EnforcementLevel = "hard-fail"
+)
+
+// Violation is a single policy rule failure.
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+// Decision is the outcome of running a PolicyChecker against a plan. It is
+// persisted alongside the stored plan so a later Apply of the same plan file
+// can't bypass the check that ran against it.
+type Decision struct {
+	Allowed     bool
+	Enforcement EnforcementLevel
+	Violations  []Violation
+	// CheckerError is set when the PolicyChecker itself failed to run (OPA
+	// binary missing, webhook unreachable, plan JSON unobtainable, ...) as
+	// opposed to running successfully and finding violations. SoftFail only
+	// blocks on violations, not on this.
+	CheckerError bool
+}
+
+// Blocks reports whether this decision should stop Apply from running,
+// given its enforcement level.
+func (d Decision) Blocks() bool {
+	if d.Allowed {
+		return false
+	}
+	if d.CheckerError {
+		return d.Enforcement == HardFail
+	}
+	return d.Enforcement == SoftFail || d.Enforcement == HardFail
+}
+
+// PolicyChecker evaluates a project's policy bundle/rules against a
+// terraform JSON plan (the output of `terraform show -json`).
+type PolicyChecker interface {
+	Check(planJSON []byte, enforcement EnforcementLevel) (Decision, error)
+}
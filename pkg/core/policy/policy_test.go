@@ -0,0 +1,26 @@
+package policy
+
+import "testing"
+
+func TestDecisionBlocks(t *testing.T) {
+	cases := []struct {
+		name     string
+		decision Decision
+		want     bool
+	}{
+		{"allowed never blocks", Decision{Allowed: true, Enforcement: HardFail}, false},
+		{"advisory violation does not block", Decision{Enforcement: Advisory, Violations: []Violation{{Rule: "r"}}}, false},
+		{"soft-fail violation blocks", Decision{Enforcement: SoftFail, Violations: []Violation{{Rule: "r"}}}, true},
+		{"hard-fail violation blocks", Decision{Enforcement: HardFail, Violations: []Violation{{Rule: "r"}}}, true},
+		{"soft-fail checker error does not block", Decision{Enforcement: SoftFail, CheckerError: true, Violations: []Violation{{Rule: "r"}}}, false},
+		{"hard-fail checker error blocks", Decision{Enforcement: HardFail, CheckerError: true, Violations: []Violation{{Rule: "r"}}}, true},
+		{"advisory checker error does not block", Decision{Enforcement: Advisory, CheckerError: true, Violations: []Violation{{Rule: "r"}}}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.decision.Blocks(); got != c.want {
+				t.Errorf("Blocks() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookChecker delegates policy evaluation to an HTTP endpoint: it POSTs
+// the JSON plan and expects {"allow": bool, "violations": [...]}.
+type WebhookChecker struct {
+	Endpoint string
+	Client   *http.Client
+}
+
+type webhookResponse struct {
+	Allow      bool   `json:"allow"`
+	Violations []struct {
+		Rule    string `json:"rule"`
+		Message string `json:"message"`
+	} `json:"violations"`
+}
+
+func (w WebhookChecker) Check(planJSON []byte, enforcement EnforcementLevel) (Decision, error) {
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	resp, err := client.Post(w.Endpoint, "application/json", bytes.NewReader(planJSON))
+	if err != nil {
+		return Decision{}, fmt.Errorf("error calling policy webhook: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return Decision{}, fmt.Errorf("policy webhook returned status %v", resp.StatusCode)
+	}
+
+	var parsed webhookResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return Decision{}, fmt.Errorf("error decoding policy webhook response: %v", err)
+	}
+
+	var violations []Violation
+	for _, v := range parsed.Violations {
+		violations = append(violations, Violation{Rule: v.Rule, Message: v.Message})
+	}
+
+	return Decision{
+		Allowed:     parsed.Allow,
+		Enforcement: enforcement,
+		Violations:  violations,
+	}, nil
+}
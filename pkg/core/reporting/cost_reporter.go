@@ -0,0 +1,22 @@
+package reporting
+
+import (
+	"digger/pkg/core/cost"
+	"fmt"
+	"math"
+)
+
+// FormatCostEstimateMarkdown renders a cost.Estimate as a one-line monthly
+// delta followed by the estimator's own breakdown, collapsed so it doesn't
+// dominate the PR conversation alongside the plan comment.
+func FormatCostEstimateMarkdown(estimate cost.Estimate) string {
+	sign := "+"
+	if estimate.MonthlyCostDelta < 0 {
+		sign = "-"
+	}
+	summary := fmt.Sprintf("Monthly cost %v%.2f %v.\n", sign, math.Abs(estimate.MonthlyCostDelta), estimate.Currency)
+	if estimate.Breakdown == "" {
+		return summary
+	}
+	return summary + fmt.Sprintf("\n<details><summary>Cost breakdown</summary>\n\n```json\n%v\n```\n</details>\n", estimate.Breakdown)
+}
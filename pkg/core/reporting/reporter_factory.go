@@ -0,0 +1,15 @@
+package reporting
+
+// ReporterFactory decouples Reporter from the assumption that there is
+// always a PR to comment on. A scheduled drift run, for instance, has no PR
+// context at all: it needs a reporter that files/updates a GitHub issue, or
+// one that just logs, instead of one tied to PR comments.
+type ReporterFactory interface {
+	// PRReporter returns a Reporter that comments on the given PR.
+	PRReporter(prNumber int) Reporter
+	// IssueReporter returns a Reporter that files/updates a tracking issue,
+	// for use when there is no PR (e.g. scheduled drift detection).
+	IssueReporter() Reporter
+	// LogReporter returns a Reporter that only logs, for local/dry runs.
+	LogReporter() Reporter
+}
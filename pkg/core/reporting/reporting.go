@@ -0,0 +1,43 @@
+package reporting
+
+import (
+	"digger/pkg/core/terraform/planjson"
+	"fmt"
+	"strings"
+)
+
+// Reporter publishes human-readable output (terraform plan/apply text,
+// errors, ...) back to wherever the operation was triggered from, usually a
+// PR comment.
+type Reporter interface {
+	Report(report string, formatter func(string) string) error
+	// ReportPlanSummary publishes the structured plan summary in addition to
+	// the raw text Report already carries, so callers can render something
+	// richer than scraped plan output (a per-resource diff table, stable
+	// status checks, etc).
+	ReportPlanSummary(summary planjson.PlanSummary) error
+}
+
+// FormatPlanSummaryMarkdown is the default ReportPlanSummary formatter: a
+// one-line totals summary followed by a collapsible per-resource diff table.
+func FormatPlanSummaryMarkdown(summary planjson.PlanSummary) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Plan: %d to add, %d to change, %d to destroy", summary.Adds, summary.Changes, summary.Destroys)
+	if summary.Replaces > 0 {
+		fmt.Fprintf(&sb, ", %d to replace", summary.Replaces)
+	}
+	sb.WriteString(".\n")
+
+	if len(summary.ResourceChanges) == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString("\n<details><summary>Resource changes</summary>\n\n")
+	sb.WriteString("| Resource | Action |\n")
+	sb.WriteString("|---|---|\n")
+	for _, rc := range summary.ResourceChanges {
+		fmt.Fprintf(&sb, "| %v | %v |\n", rc.Address, strings.Join(rc.Actions, ", "))
+	}
+	sb.WriteString("\n</details>\n")
+	return sb.String()
+}
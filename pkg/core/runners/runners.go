@@ -0,0 +1,7 @@
+package runners
+
+// CommandRun runs arbitrary shell commands on behalf of a "run" pipeline
+// step, in the given working directory.
+type CommandRun interface {
+	Run(workingDir string, shell string, commands []string) (string, string, error)
+}
@@ -0,0 +1,16 @@
+package storage
+
+// PlanStorage persists a project's tfplan file somewhere durable (e.g. S3,
+// GCS, a git branch) so that a later Apply can retrieve the exact plan that
+// was reviewed, even if it runs on a different machine.
+type PlanStorage interface {
+	StorePlan(localPlanFilePath string, storedPlanFilePath string) error
+	RetrievePlan(localPlanFilePath string, storedPlanFilePath string) (*string, error)
+	DeleteStoredPlan(storedPlanFilePath string) error
+	PlanExists(storedPlanFilePath string) (bool, error)
+	// StorePlanMetadata persists an arbitrary blob (e.g. a serialized policy
+	// decision) alongside a stored plan, keyed by suffix, so a later Apply of
+	// the same plan file can look it up again.
+	StorePlanMetadata(storedPlanFilePath string, suffix string, data []byte) error
+	RetrievePlanMetadata(storedPlanFilePath string, suffix string) ([]byte, error)
+}
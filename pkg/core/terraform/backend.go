@@ -0,0 +1,32 @@
+package terraform
+
+import (
+	"digger/pkg/core/models"
+	"fmt"
+)
+
+// NewExecutorForBackend selects the TerraformExecutor for a project's
+// `backend:` config block: BackendLocal keeps using localExecutor (already
+// configured for the project's working directory), while BackendRemote
+// delegates to Terraform Cloud/Enterprise via RemoteBackendExecutor. This is
+// the switch that lets a project move onto TFC purely through config,
+// without DiggerExecutor itself knowing the difference beyond the
+// RemotePlanIdentifier check it already does.
+func NewExecutorForBackend(cfg models.BackendConfig, localExecutor TerraformExecutor, workingDir string) (TerraformExecutor, error) {
+	switch cfg.Type {
+	case "", models.BackendLocal:
+		return localExecutor, nil
+	case models.BackendRemote:
+		if cfg.Remote == nil {
+			return nil, fmt.Errorf("backend type is '%v' but no remote config was provided", models.BackendRemote)
+		}
+		return NewRemoteBackendExecutor(RemoteBackendConfig{
+			Hostname:     cfg.Remote.Hostname,
+			Organization: cfg.Remote.Organization,
+			Workspace:    cfg.Remote.Workspace,
+			TokenEnv:     cfg.Remote.TokenEnv,
+		}, workingDir), nil
+	default:
+		return nil, fmt.Errorf("unknown backend type: %v", cfg.Type)
+	}
+}
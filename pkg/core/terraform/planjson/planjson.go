@@ -0,0 +1,107 @@
+// Package planjson parses the output of `terraform show -json <planfile>`
+// into a typed summary, instead of scraping the human-readable plan text the
+// way cleanupTerraformPlan does. This is the same shift Terraform itself made
+// internally (plans.Plan / states.State carrying structured data rather than
+// strings), and it lets Digger reason about a plan's blast radius instead of
+// just forwarding text.
+package planjson
+
+import "encoding/json"
+
+// ResourceChange is one entry of a terraform plan's resource_changes list,
+// trimmed to the fields Digger needs to report/gate on.
+type ResourceChange struct {
+	Address      string   `json:"address"`
+	Type         string   `json:"type"`
+	Name         string   `json:"name"`
+	ProviderName string   `json:"provider_name"`
+	Actions      []string `json:"actions"`
+}
+
+// PlanSummary is the structured equivalent of what cleanupTerraformPlan used
+// to scrape out of stdout.
+type PlanSummary struct {
+	Adds            int
+	Changes         int
+	Destroys        int
+	Replaces        int
+	Drift           int
+	ResourceChanges []ResourceChange
+}
+
+// HasDestroys reports whether any resource in the plan is destroyed or
+// replaced (replace = destroy + create), e.g. for a caller that gates
+// applies on extra approval.
+func (s PlanSummary) HasDestroys() bool {
+	return s.Destroys > 0 || s.Replaces > 0
+}
+
+type rawPlan struct {
+	ResourceChanges []rawResourceChange `json:"resource_changes"`
+}
+
+type rawResourceChange struct {
+	Address      string `json:"address"`
+	Type         string `json:"type"`
+	Name         string `json:"name"`
+	ProviderName string `json:"provider_name"`
+	Change       struct {
+		Actions []string `json:"actions"`
+	} `json:"change"`
+}
+
+// Parse reads the output of `terraform show -json <planfile>` and builds a
+// PlanSummary out of its resource_changes.
+func Parse(planJSON []byte) (PlanSummary, error) {
+	var raw rawPlan
+	if err := json.Unmarshal(planJSON, &raw); err != nil {
+		return PlanSummary{}, err
+	}
+
+	summary := PlanSummary{}
+	for _, rc := range raw.ResourceChanges {
+		change := ResourceChange{
+			Address:      rc.Address,
+			Type:         rc.Type,
+			Name:         rc.Name,
+			ProviderName: rc.ProviderName,
+			Actions:      rc.Change.Actions,
+		}
+		summary.ResourceChanges = append(summary.ResourceChanges, change)
+
+		switch actionKind(rc.Change.Actions) {
+		case "create":
+			summary.Adds++
+		case "update":
+			summary.Changes++
+		case "delete":
+			summary.Destroys++
+		case "replace":
+			summary.Replaces++
+		}
+	}
+	return summary, nil
+}
+
+func actionKind(actions []string) string {
+	has := func(a string) bool {
+		for _, x := range actions {
+			if x == a {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case has("create") && has("delete"):
+		return "replace"
+	case has("create"):
+		return "create"
+	case has("update"):
+		return "update"
+	case has("delete"):
+		return "delete"
+	default:
+		return "no-op"
+	}
+}
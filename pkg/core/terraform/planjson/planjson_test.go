@@ -0,0 +1,53 @@
+package planjson
+
+import "testing"
+
+func TestActionKind(t *testing.T) {
+	cases := []struct {
+		name    string
+		actions []string
+		want    string
+	}{
+		{"create", []string{"create"}, "create"},
+		{"update", []string{"update"}, "update"},
+		{"delete", []string{"delete"}, "delete"},
+		{"replace", []string{"create", "delete"}, "replace"},
+		{"no-op", []string{"no-op"}, "no-op"},
+		{"empty", nil, "no-op"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := actionKind(c.actions); got != c.want {
+				t.Errorf("actionKind(%v) = %v, want %v", c.actions, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	planJSON := `{
+		"resource_changes": [
+			{"address": "aws_instance.a", "type": "aws_instance", "name": "a", "provider_name": "aws", "change": {"actions": ["create"]}},
+			{"address": "aws_instance.b", "type": "aws_instance", "name": "b", "provider_name": "aws", "change": {"actions": ["delete"]}},
+			{"address": "aws_instance.c", "type": "aws_instance", "name": "c", "provider_name": "aws", "change": {"actions": ["create", "delete"]}},
+			{"address": "aws_instance.d", "type": "aws_instance", "name": "d", "provider_name": "aws", "change": {"actions": ["update"]}}
+		]
+	}`
+
+	summary, err := Parse([]byte(planJSON))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if summary.Adds != 1 || summary.Destroys != 1 || summary.Replaces != 1 || summary.Changes != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if !summary.HasDestroys() {
+		t.Fatalf("HasDestroys() = false, want true")
+	}
+	if len(summary.ResourceChanges) != 4 {
+		t.Fatalf("got %d resource changes, want 4", len(summary.ResourceChanges))
+	}
+	if got := summary.ResourceChanges[0].Actions; len(got) != 1 || got[0] != "create" {
+		t.Errorf("ResourceChanges[0].Actions = %v, want [create]", got)
+	}
+}
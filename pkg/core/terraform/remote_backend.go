@@ -0,0 +1,299 @@
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// RemoteBackendConfig points a project at Terraform Cloud/Enterprise instead
+// of a local terraform binary, selected via a project's `backend: remote {}`
+// config block.
+type RemoteBackendConfig struct {
+	Hostname     string
+	Organization string
+	Workspace    string
+	TokenEnv     string // env var holding the TFC/TFE API token
+}
+
+// RemotePlanIdentifier is implemented by TerraformExecutors where the
+// backend itself is the plan's identity (a TFC run ID rather than a local
+// .tfplan file), so DiggerExecutor can short-circuit its own PlanStorage.
+type RemotePlanIdentifier interface {
+	PlanIdentity() string
+}
+
+// RemoteBackendExecutor implements TerraformExecutor by driving a run
+// through the Terraform Cloud/Enterprise API instead of running terraform
+// locally: it creates a run against the workspace and polls it to
+// completion. It does not create or upload a configuration version (WorkingDir
+// is not sent), so the run uses whatever configuration the workspace already
+// has, and run logs are not streamed. This is analogous to how Terraform's
+// own `remote` enhanced backend delegates plan/apply to TFC.
+type RemoteBackendExecutor struct {
+	Config     RemoteBackendConfig
+	WorkingDir string
+	Client     *http.Client
+	PollEvery  time.Duration
+
+	lastRunID string
+}
+
+func NewRemoteBackendExecutor(config RemoteBackendConfig, workingDir string) *RemoteBackendExecutor {
+	return &RemoteBackendExecutor{Config: config, WorkingDir: workingDir, PollEvery: 5 * time.Second}
+}
+
+// PlanIdentity returns the TFC run ID of the last Plan, which is what
+// Apply needs to resume the same run rather than a local plan file.
+func (r *RemoteBackendExecutor) PlanIdentity() string {
+	return r.lastRunID
+}
+
+func (r *RemoteBackendExecutor) client() *http.Client {
+	if r.Client != nil {
+		return r.Client
+	}
+	return http.DefaultClient
+}
+
+func (r *RemoteBackendExecutor) apiBase() string {
+	return fmt.Sprintf("https://%v/api/v2", r.Config.Hostname)
+}
+
+func (r *RemoteBackendExecutor) do(method, url string, body interface{}) (map[string]interface{}, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling request: %v", err)
+		}
+		reader = bytes.NewReader(payload)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("error building request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+os.Getenv(r.Config.TokenEnv))
+	req.Header.Set("Content-Type", "application/vnd.api+json")
+
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling %v: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%v returned status %v", url, resp.StatusCode)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("error decoding response from %v: %v", url, err)
+	}
+	return parsed, nil
+}
+
+// Init is a no-op: TFC runs init server-side as part of the run itself. It
+// exists purely so RemoteBackendExecutor satisfies TerraformExecutor.
+func (r *RemoteBackendExecutor) Init(params []string, envs map[string]string) (string, string, error) {
+	return "", "", nil
+}
+
+func (r *RemoteBackendExecutor) createRun(isDestroy bool) (string, error) {
+	body := map[string]interface{}{
+		"data": map[string]interface{}{
+			"type": "runs",
+			"attributes": map[string]interface{}{
+				"is-destroy": isDestroy,
+			},
+			"relationships": map[string]interface{}{
+				"workspace": map[string]interface{}{
+					"data": map[string]interface{}{
+						"type": "workspaces",
+						"id":   r.Config.Workspace,
+					},
+				},
+			},
+		},
+	}
+	resp, err := r.do(http.MethodPost, r.apiBase()+"/runs", body)
+	if err != nil {
+		return "", fmt.Errorf("error creating run: %v", err)
+	}
+	data, _ := resp["data"].(map[string]interface{})
+	id, _ := data["id"].(string)
+	if id == "" {
+		return "", fmt.Errorf("run creation response did not include an id")
+	}
+	return id, nil
+}
+
+// pollRun polls a run until it reaches one of the given terminal phases,
+// sleeping PollEvery between checks.
+func (r *RemoteBackendExecutor) pollRun(runID string, terminal []string) (string, error) {
+	for {
+		resp, err := r.do(http.MethodGet, r.apiBase()+"/runs/"+runID, nil)
+		if err != nil {
+			return "", fmt.Errorf("error polling run %v: %v", runID, err)
+		}
+		data, _ := resp["data"].(map[string]interface{})
+		attrs, _ := data["attributes"].(map[string]interface{})
+		status, _ := attrs["status"].(string)
+
+		for _, t := range terminal {
+			if status == t {
+				return status, nil
+			}
+		}
+		time.Sleep(r.PollEvery)
+	}
+}
+
+func (r *RemoteBackendExecutor) Plan(params []string, envs map[string]string) (bool, string, string, error) {
+	runID, err := r.createRun(false)
+	if err != nil {
+		return false, "", "", err
+	}
+	r.lastRunID = runID
+
+	status, err := r.pollRun(runID, []string{"planned", "planned_and_finished", "errored", "canceled"})
+	if err != nil {
+		return false, "", "", err
+	}
+	if status == "errored" {
+		return false, "", "", fmt.Errorf("remote run %v errored", runID)
+	}
+	if status == "canceled" {
+		return false, "", "", fmt.Errorf("remote run %v was canceled", runID)
+	}
+	return status == "planned", fmt.Sprintf("remote run %v: %v", runID, status), "", nil
+}
+
+func (r *RemoteBackendExecutor) Apply(params []string, plansFilename *string, envs map[string]string) (string, string, error) {
+	runID := r.lastRunID
+	if plansFilename != nil && *plansFilename != "" {
+		runID = *plansFilename
+	}
+	if runID == "" {
+		return "", "", fmt.Errorf("no remote run to apply")
+	}
+
+	if _, err := r.do(http.MethodPost, r.apiBase()+"/runs/"+runID+"/actions/apply", map[string]interface{}{}); err != nil {
+		return "", "", fmt.Errorf("error applying run %v: %v", runID, err)
+	}
+
+	status, err := r.pollRun(runID, []string{"applied", "errored", "canceled"})
+	if err != nil {
+		return "", "", err
+	}
+	if status != "applied" {
+		return "", "", fmt.Errorf("remote run %v finished as %v", runID, status)
+	}
+	return fmt.Sprintf("remote run %v: %v", runID, status), "", nil
+}
+
+func (r *RemoteBackendExecutor) Destroy(params []string, envs map[string]string) (string, string, error) {
+	runID, err := r.createRun(true)
+	if err != nil {
+		return "", "", err
+	}
+	r.lastRunID = runID
+	if _, err := r.do(http.MethodPost, r.apiBase()+"/runs/"+runID+"/actions/apply", map[string]interface{}{}); err != nil {
+		return "", "", fmt.Errorf("error applying destroy run %v: %v", runID, err)
+	}
+	status, err := r.pollRun(runID, []string{"applied", "errored", "canceled"})
+	if err != nil {
+		return "", "", err
+	}
+	return fmt.Sprintf("remote run %v: %v", runID, status), "", nil
+}
+
+func (r *RemoteBackendExecutor) ProvidersLock(platforms []string, envs map[string]string) (string, string, error) {
+	return "", "", fmt.Errorf("providers lock is not supported with the remote backend: TFC manages provider locking server-side")
+}
+
+// ShowPlanJSON fetches the structured plan JSON for the last run from TFC's
+// plan json-output link, the same `terraform show -json`-compatible
+// resource_changes document local runs produce, rather than running
+// `terraform show -json` locally. planFilePath is ignored: there's no local
+// file for a remote run, so callers should pass "" (see
+// DiggerExecutor.usesRemotePlanIdentity).
+func (r *RemoteBackendExecutor) ShowPlanJSON(planFilePath string, envs map[string]string) (string, error) {
+	if r.lastRunID == "" {
+		return "", fmt.Errorf("no remote run to show a plan for")
+	}
+
+	planID, err := r.planIDForRun(r.lastRunID)
+	if err != nil {
+		return "", fmt.Errorf("error finding plan for run %v: %v", r.lastRunID, err)
+	}
+
+	body, err := r.rawGet(r.apiBase() + "/plans/" + planID + "/json-output")
+	if err != nil {
+		return "", fmt.Errorf("error fetching JSON plan output for run %v: %v", r.lastRunID, err)
+	}
+	return string(body), nil
+}
+
+// planIDForRun looks up the plan resource associated with a run, since the
+// json-output link lives under /plans/{id}, not /runs/{id}.
+func (r *RemoteBackendExecutor) planIDForRun(runID string) (string, error) {
+	resp, err := r.do(http.MethodGet, r.apiBase()+"/runs/"+runID, nil)
+	if err != nil {
+		return "", fmt.Errorf("error fetching run %v: %v", runID, err)
+	}
+	data, _ := resp["data"].(map[string]interface{})
+	relationships, _ := data["relationships"].(map[string]interface{})
+	planRel, _ := relationships["plan"].(map[string]interface{})
+	planData, _ := planRel["data"].(map[string]interface{})
+	id, _ := planData["id"].(string)
+	if id == "" {
+		return "", fmt.Errorf("run %v has no associated plan", runID)
+	}
+	return id, nil
+}
+
+// rawGet fetches a URL that returns a raw (non JSON:API) body, unlike do's
+// JSON:API envelope decoding.
+func (r *RemoteBackendExecutor) rawGet(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error building request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+os.Getenv(r.Config.TokenEnv))
+
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling %v: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%v returned status %v", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (r *RemoteBackendExecutor) PlanRefreshOnly(envs map[string]string) (int, string, string, error) {
+	return 0, "", "", fmt.Errorf("drift detection is not yet supported with the remote backend")
+}
+
+// CancelRun cancels an in-flight remote run. A TFC webhook handler that
+// observes a run land in "canceled" should translate that into releasing
+// Digger's own project lock (LockingExecutorWrapper.Unlock), since the
+// remote system becomes the source of truth for whether work is in flight
+// once a run has been handed off to it.
+func (r *RemoteBackendExecutor) CancelRun(runID string) error {
+	_, err := r.do(http.MethodPost, r.apiBase()+"/runs/"+runID+"/actions/cancel", map[string]interface{}{})
+	if err != nil {
+		return fmt.Errorf("error canceling run %v: %v", runID, err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package terraform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// LockHolder describes whoever currently holds a state lock, so it can be
+// surfaced to the user when acquisition fails instead of just "locked".
+type LockHolder struct {
+	LockID    string
+	Who       string
+	Operation string
+	Created   time.Time
+}
+
+// StateLocker takes and releases a lock on the remote state backend itself
+// (S3+Dynamo, GCS, HTTP backend, ...), independent of Digger's own
+// ProjectLock. Terraform's backend.Operation takes a similar lock for the
+// duration of a plan/apply; Digger needs the same guarantee so that a
+// concurrent out-of-band `terraform apply` or a second CI run can't stomp on
+// the same state file. HTTPBackendStateLocker, DynamoDBStateLocker and
+// GCSStateLocker implement this against the three backends Digger projects
+// actually use, each by acquiring the lock the same way that backend's own
+// Terraform implementation would.
+type StateLocker interface {
+	LockState(ctx context.Context) error
+	UnlockState(ctx context.Context) error
+	// CurrentLockHolder returns who holds the lock when LockState fails
+	// because it is already held. It returns nil if the lock is free or the
+	// holder can't be determined.
+	CurrentLockHolder(ctx context.Context) (*LockHolder, error)
+}
+
+// HTTPBackendStateLocker holds the state lock for the duration of a Digger
+// operation by calling the backend's own lock RPC directly (the LOCK/UNLOCK
+// verbs Terraform's `http` backend exposes at lock_address/unlock_address),
+// rather than shelling out to a terraform subcommand that acquires and
+// releases the lock again within a single invocation. This is the same
+// mechanism Terraform's own S3/GCS/http backends use to implement
+// statemgr.Locker against the backend itself, so the lock stays held across
+// Digger's separate init/plan/apply invocations until UnlockState is called.
+type HTTPBackendStateLocker struct {
+	LockAddress   string
+	UnlockAddress string
+	Who           string // identifies the holder, e.g. "digger/ns/project"
+	Client        *http.Client
+
+	lockID string
+}
+
+func (l *HTTPBackendStateLocker) client() *http.Client {
+	if l.Client != nil {
+		return l.Client
+	}
+	return http.DefaultClient
+}
+
+type lockInfo struct {
+	ID        string    `json:"ID"`
+	Who       string    `json:"Who"`
+	Operation string    `json:"Operation"`
+	Created   time.Time `json:"Created"`
+}
+
+// LockState acquires the lock and keeps it held (lockID is remembered on the
+// receiver) until UnlockState releases it.
+func (l *HTTPBackendStateLocker) LockState(ctx context.Context) error {
+	info := lockInfo{
+		ID:        fmt.Sprintf("digger-%v-%v", l.Who, time.Now().UTC().UnixNano()),
+		Who:       l.Who,
+		Operation: "OperationTypePlan",
+		Created:   time.Now().UTC(),
+	}
+	status, body, err := l.call(ctx, "LOCK", l.LockAddress, info)
+	if err != nil {
+		return fmt.Errorf("error locking state: %v", err)
+	}
+	switch status {
+	case http.StatusOK:
+		l.lockID = info.ID
+		return nil
+	case http.StatusLocked:
+		return fmt.Errorf("state is locked: %s", body)
+	default:
+		return fmt.Errorf("unexpected status %v locking state: %s", status, body)
+	}
+}
+
+func (l *HTTPBackendStateLocker) UnlockState(ctx context.Context) error {
+	if l.lockID == "" {
+		return nil
+	}
+	status, body, err := l.call(ctx, "UNLOCK", l.UnlockAddress, lockInfo{ID: l.lockID})
+	if err != nil {
+		return fmt.Errorf("error unlocking state: %v", err)
+	}
+	if status >= 300 {
+		return fmt.Errorf("unexpected status %v unlocking state: %s", status, body)
+	}
+	l.lockID = ""
+	return nil
+}
+
+// CurrentLockHolder probes the lock without holding it: it attempts a LOCK
+// with a throwaway ID, immediately releasing it again if it succeeds (no one
+// else holds the lock), or parsing the existing holder out of the 423
+// response if it doesn't.
+func (l *HTTPBackendStateLocker) CurrentLockHolder(ctx context.Context) (*LockHolder, error) {
+	probe := lockInfo{ID: fmt.Sprintf("digger-probe-%v", time.Now().UTC().UnixNano()), Who: l.Who}
+	status, body, err := l.call(ctx, "LOCK", l.LockAddress, probe)
+	if err != nil {
+		return nil, fmt.Errorf("error probing state lock: %v", err)
+	}
+
+	if status == http.StatusOK {
+		if _, _, err := l.call(ctx, "UNLOCK", l.UnlockAddress, probe); err != nil {
+			return nil, fmt.Errorf("error releasing probe lock: %v", err)
+		}
+		return nil, nil
+	}
+	if status != http.StatusLocked {
+		return nil, fmt.Errorf("unexpected status %v probing state lock: %s", status, body)
+	}
+
+	var held lockInfo
+	if err := json.Unmarshal(body, &held); err != nil {
+		return nil, nil
+	}
+	return &LockHolder{LockID: held.ID, Who: held.Who, Operation: held.Operation, Created: held.Created}, nil
+}
+
+func (l *HTTPBackendStateLocker) call(ctx context.Context, method, url string, info lockInfo) (int, []byte, error) {
+	payload, err := json.Marshal(info)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error marshalling lock info: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, nil, fmt.Errorf("error building %v request: %v", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := l.client().Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error calling %v %v: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error reading %v response: %v", method, err)
+	}
+	return resp.StatusCode, body, nil
+}
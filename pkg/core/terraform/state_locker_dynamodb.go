@@ -0,0 +1,104 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDBStateLocker implements StateLocker against the same DynamoDB
+// locking table Terraform's s3 backend uses: a conditional PutItem keyed on
+// LockID (attribute_not_exists) is how that backend's own statemgr.Locker
+// acquires the lock in the first place, so holding it the same way means
+// Digger and a concurrent out-of-band `terraform apply` genuinely contend
+// for one lock rather than two independent ones.
+type DynamoDBStateLocker struct {
+	Client    *dynamodb.Client
+	TableName string
+	LockID    string // matches the s3 backend's own LockID, e.g. "<bucket>/<key>"
+	Who       string
+
+	held bool
+}
+
+type dynamoDBLockInfo struct {
+	ID        string `json:"ID"`
+	Who       string `json:"Who"`
+	Operation string `json:"Operation"`
+	Created   string `json:"Created"`
+	Path      string `json:"Path"`
+}
+
+func (l *DynamoDBStateLocker) LockState(ctx context.Context) error {
+	info := dynamoDBLockInfo{
+		ID:        fmt.Sprintf("digger-%v-%v", l.Who, time.Now().UTC().UnixNano()),
+		Who:       l.Who,
+		Operation: "OperationTypePlan",
+		Created:   time.Now().UTC().Format(time.RFC3339),
+		Path:      l.LockID,
+	}
+	payload, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("error marshalling lock info: %v", err)
+	}
+
+	_, err = l.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(l.TableName),
+		Item: map[string]types.AttributeValue{
+			"LockID": &types.AttributeValueMemberS{Value: l.LockID},
+			"Info":   &types.AttributeValueMemberS{Value: string(payload)},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(LockID)"),
+	})
+	if err != nil {
+		return fmt.Errorf("state is locked: %v", err)
+	}
+	l.held = true
+	return nil
+}
+
+func (l *DynamoDBStateLocker) UnlockState(ctx context.Context) error {
+	if !l.held {
+		return nil
+	}
+	_, err := l.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(l.TableName),
+		Key: map[string]types.AttributeValue{
+			"LockID": &types.AttributeValueMemberS{Value: l.LockID},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error unlocking state: %v", err)
+	}
+	l.held = false
+	return nil
+}
+
+func (l *DynamoDBStateLocker) CurrentLockHolder(ctx context.Context) (*LockHolder, error) {
+	out, err := l.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(l.TableName),
+		Key:            map[string]types.AttributeValue{"LockID": &types.AttributeValueMemberS{Value: l.LockID}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading state lock: %v", err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+	raw, ok := out.Item["Info"].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, nil
+	}
+	var info dynamoDBLockInfo
+	if err := json.Unmarshal([]byte(raw.Value), &info); err != nil {
+		return nil, nil
+	}
+	created, _ := time.Parse(time.RFC3339, info.Created)
+	return &LockHolder{LockID: info.ID, Who: info.Who, Operation: info.Operation, Created: created}, nil
+}
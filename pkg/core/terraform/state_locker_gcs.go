@@ -0,0 +1,95 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"cloud.google.com/go/storage"
+)
+
+// GCSStateLocker implements StateLocker against the same lock object
+// Terraform's gcs backend writes: an object created with a
+// generation-match-zero precondition, which only succeeds if the object
+// doesn't already exist, mirroring how the gcs backend's own
+// statemgr.Locker acquires the lock.
+type GCSStateLocker struct {
+	Client     *storage.Client
+	Bucket     string
+	LockObject string
+	Who        string
+
+	lockID     string
+	generation int64
+}
+
+type gcsLockInfo struct {
+	ID        string    `json:"ID"`
+	Who       string    `json:"Who"`
+	Operation string    `json:"Operation"`
+	Created   time.Time `json:"Created"`
+}
+
+func (l *GCSStateLocker) object() *storage.ObjectHandle {
+	return l.Client.Bucket(l.Bucket).Object(l.LockObject)
+}
+
+func (l *GCSStateLocker) LockState(ctx context.Context) error {
+	info := gcsLockInfo{
+		ID:        fmt.Sprintf("digger-%v-%v", l.Who, time.Now().UTC().UnixNano()),
+		Who:       l.Who,
+		Operation: "OperationTypePlan",
+		Created:   time.Now().UTC(),
+	}
+	payload, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("error marshalling lock info: %v", err)
+	}
+
+	w := l.object().If(storage.Conditions{DoesNotExist: true}).NewWriter(ctx)
+	if _, err := w.Write(payload); err != nil {
+		w.Close()
+		return fmt.Errorf("error writing lock object: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("state is locked: %v", err)
+	}
+	l.lockID = info.ID
+	l.generation = w.Attrs().Generation
+	return nil
+}
+
+func (l *GCSStateLocker) UnlockState(ctx context.Context) error {
+	if l.lockID == "" {
+		return nil
+	}
+	if err := l.object().If(storage.Conditions{GenerationMatch: l.generation}).Delete(ctx); err != nil {
+		return fmt.Errorf("error unlocking state: %v", err)
+	}
+	l.lockID = ""
+	return nil
+}
+
+func (l *GCSStateLocker) CurrentLockHolder(ctx context.Context) (*LockHolder, error) {
+	r, err := l.object().NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading state lock: %v", err)
+	}
+	defer r.Close()
+
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading state lock: %v", err)
+	}
+	var info gcsLockInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, nil
+	}
+	return &LockHolder{LockID: info.ID, Who: info.Who, Operation: info.Operation, Created: info.Created}, nil
+}
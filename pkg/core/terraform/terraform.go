@@ -0,0 +1,23 @@
+package terraform
+
+// TerraformExecutor runs the terraform CLI against a single project
+// directory.
+type TerraformExecutor interface {
+	Init(params []string, envs map[string]string) (string, string, error)
+	Plan(params []string, envs map[string]string) (bool, string, string, error)
+	Apply(params []string, plansFilename *string, envs map[string]string) (string, string, error)
+	Destroy(params []string, envs map[string]string) (string, string, error)
+	// ProvidersLock runs `terraform providers lock` for the given target
+	// platforms (e.g. "linux_amd64", "darwin_arm64"), updating
+	// .terraform.lock.hcl with hashes for platforms other than the one
+	// Digger itself runs on.
+	ProvidersLock(platforms []string, envs map[string]string) (string, string, error)
+	// ShowPlanJSON runs `terraform show -json <planFilePath>` and returns its
+	// stdout, so callers can parse a structured plan instead of scraping
+	// human-readable output.
+	ShowPlanJSON(planFilePath string, envs map[string]string) (string, error)
+	// PlanRefreshOnly runs `terraform plan -detailed-exitcode -refresh-only`
+	// for drift detection. exitCode is terraform's own detailed exit code:
+	// 0 = no changes, 1 = error, 2 = drift detected.
+	PlanRefreshOnly(envs map[string]string) (exitCode int, stdout string, stderr string, err error)
+}
@@ -0,0 +1,20 @@
+package utils
+
+import "fmt"
+
+// GetTerraformOutputAsCollapsibleComment renders terraform output as a
+// collapsible markdown section under the given title, so long plan/apply
+// output doesn't dominate the PR conversation.
+func GetTerraformOutputAsCollapsibleComment(title string) func(string) string {
+	return func(body string) string {
+		return fmt.Sprintf("<details><summary>%v</summary>\n\n```terraform\n%v\n```\n</details>", title, body)
+	}
+}
+
+// AsCollapsibleComment is like GetTerraformOutputAsCollapsibleComment but for
+// arbitrary (non-terraform) text, e.g. error messages.
+func AsCollapsibleComment(title string) func(string) string {
+	return func(body string) string {
+		return fmt.Sprintf("<details><summary>%v</summary>\n\n%v\n</details>", title, body)
+	}
+}
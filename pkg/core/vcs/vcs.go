@@ -0,0 +1,9 @@
+package vcs
+
+// VCSCommitter commits a file back to the PR branch. It is used for
+// artifacts Digger generates as a side effect of running terraform (e.g.
+// .terraform.lock.hcl) that need to live in the repo rather than just being
+// reported in a comment.
+type VCSCommitter interface {
+	CommitFile(branch string, path string, content []byte, message string) error
+}